@@ -0,0 +1,42 @@
+// Package api holds the internal (unversioned) API types for BuildConfigs, mirroring the shape
+// of the real github.com/openshift/origin/pkg/build/api package. Only the pieces needed by
+// pkg/deploy/graph are reproduced here.
+package api
+
+import (
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// BuildConfig is a template for creating Builds.
+type BuildConfig struct {
+	kapi.TypeMeta   `json:",inline"`
+	kapi.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec BuildConfigSpec `json:"spec"`
+}
+
+// BuildConfigSpec describes when and how builds are created.
+type BuildConfigSpec struct {
+	Triggers []BuildTriggerPolicy `json:"triggers,omitempty"`
+}
+
+// BuildTriggerPolicy describes a policy for a single trigger that results in a new Build.
+type BuildTriggerPolicy struct {
+	Type        BuildTriggerType    `json:"type,omitempty"`
+	ImageChange *ImageChangeTrigger `json:"imageChange,omitempty"`
+}
+
+// BuildTriggerType refers to a specific BuildTriggerPolicy implementation.
+type BuildTriggerType string
+
+const (
+	// ImageChangeBuildTriggerType will trigger a build when a new image is available.
+	ImageChangeBuildTriggerType BuildTriggerType = "ImageChange"
+)
+
+// ImageChangeTrigger allows builds to be triggered when an ImageStreamTag changes.
+type ImageChangeTrigger struct {
+	// From is a reference to an image stream tag to watch for changes. If empty, this trigger
+	// refers to the image named in the build strategy.
+	From *kapi.ObjectReference `json:"from,omitempty"`
+}
@@ -0,0 +1,29 @@
+// Package client holds the OpenShift REST client interfaces used by the deploy controllers,
+// mirroring the shape of the real github.com/openshift/origin/pkg/client package. Only the
+// pieces needed by pkg/deploy/controller/imagechange are reproduced here.
+package client
+
+import (
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// Interface is the subset of the OpenShift REST client used by the deploy controllers.
+type Interface interface {
+	DeploymentConfigs(namespace string) DeploymentConfigInterface
+	ImageStreamImages(namespace string) ImageStreamImageInterface
+}
+
+// DeploymentConfigInterface has methods to work with DeploymentConfig resources in a namespace.
+type DeploymentConfigInterface interface {
+	Get(name string) (*deployapi.DeploymentConfig, error)
+	Update(config *deployapi.DeploymentConfig) (*deployapi.DeploymentConfig, error)
+}
+
+// ImageStreamImageInterface has methods to look up the Image backing an ImageStreamTag by
+// digest.
+type ImageStreamImageInterface interface {
+	// Get returns the Image named id ("sha256:...") as seen through the ImageStream named
+	// streamName.
+	Get(streamName, id string) (*imageapi.Image, error)
+}
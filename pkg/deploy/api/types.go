@@ -0,0 +1,128 @@
+// Package api holds the internal (unversioned) API types for DeploymentConfigs, mirroring the
+// shape of the real github.com/openshift/origin/pkg/deploy/api package. Only the pieces needed
+// by pkg/deploy/controller/imagechange and pkg/deploy/graph are reproduced here.
+package api
+
+import (
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// DeploymentConfig represents a configuration for a single deployment (represented as a
+// ReplicationController) created from a template (ControllerTemplate).
+type DeploymentConfig struct {
+	kapi.TypeMeta   `json:",inline"`
+	kapi.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DeploymentConfigSpec   `json:"spec"`
+	Status DeploymentConfigStatus `json:"status,omitempty"`
+}
+
+// DeploymentConfigSpec represents the desired state of a DeploymentConfig.
+type DeploymentConfigSpec struct {
+	// Triggers determine how updates to a DeploymentConfig result in new deployments.
+	Triggers []DeploymentTriggerPolicy `json:"triggers"`
+	// Paused means that the deployment config is paused, so the controller will ignore automatic
+	// triggers (image change, config change) but still accept manual deployments.
+	Paused bool `json:"paused,omitempty"`
+	// Template is the object that describes the pod that will be created for every deployment.
+	Template kapi.PodTemplateSpec `json:"template"`
+}
+
+// DeploymentConfigStatus represents the current deployment state.
+type DeploymentConfigStatus struct {
+	LatestVersion int64 `json:"latestVersion,omitempty"`
+}
+
+// DeploymentTriggerPolicy describes a policy that will result in a new deployment.
+type DeploymentTriggerPolicy struct {
+	// Type of the trigger.
+	Type DeploymentTriggerType `json:"type,omitempty"`
+	// ImageChangeParams represents the parameters for the ImageChange trigger.
+	ImageChangeParams *DeploymentTriggerImageChangeParams `json:"imageChangeParams,omitempty"`
+}
+
+// DeploymentTriggerType refers to a specific DeploymentTriggerPolicy implementation.
+type DeploymentTriggerType string
+
+const (
+	// DeploymentTriggerOnImageChange will create new deployments in response to updated tags
+	// from one or more images.
+	DeploymentTriggerOnImageChange DeploymentTriggerType = "ImageChange"
+	// DeploymentTriggerOnConfigChange will create new deployments in response to changes to the
+	// ControllerTemplate of a DeploymentConfig.
+	DeploymentTriggerOnConfigChange DeploymentTriggerType = "ConfigChange"
+)
+
+// ImageResolutionPolicy controls how an ImageChange trigger resolves the image reference it
+// writes into a container.
+type ImageResolutionPolicy string
+
+const (
+	// ImageResolutionPolicyTag follows the mutable tag, writing whatever
+	// DockerImageReference the tag currently points at. This is the default.
+	ImageResolutionPolicyTag ImageResolutionPolicy = "Tag"
+	// ImageResolutionPolicyDigest pins to the manifest digest backing the tag at the time the
+	// trigger fires, giving a reproducible (but not architecture-specific) reference. A tag
+	// backed by a manifest list is rejected under this policy; only a single-platform manifest
+	// is pinned.
+	ImageResolutionPolicyDigest ImageResolutionPolicy = "Digest"
+	// ImageResolutionPolicyPlatformDigest pins to the digest of the child manifest matching
+	// Platform within a manifest list (Docker schema 2 manifest list or OCI image index),
+	// giving a reproducible, architecture-specific reference.
+	ImageResolutionPolicyPlatformDigest ImageResolutionPolicy = "PlatformDigest"
+)
+
+// DeploymentTriggerImageChangePlatform selects a single architecture/OS/variant manifest out of
+// a manifest list. OS and Architecture are required; Variant is optional (for example "v8" for
+// arm64 variants).
+type DeploymentTriggerImageChangePlatform struct {
+	OS           string `json:"os"`
+	Architecture string `json:"architecture"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+// DeploymentTriggerImageChangeParams represents the parameters to the ImageChange trigger.
+type DeploymentTriggerImageChangeParams struct {
+	// Automatic means that the detection of a new tag value should result in a new deployment.
+	Automatic bool `json:"automatic,omitempty"`
+	// ContainerNames is used to restrict tag updates to the specified set of container names in
+	// a pod.
+	ContainerNames []string `json:"containerNames,omitempty"`
+	// From is a reference to an image stream tag to watch for changes.
+	From kapi.ObjectReference `json:"from"`
+	// LastTriggeredImage is the last image to be triggered.
+	LastTriggeredImage string `json:"lastTriggeredImage,omitempty"`
+
+	// ResolutionPolicy controls how the reference written into the container is resolved. The
+	// zero value is equivalent to ImageResolutionPolicyTag.
+	ResolutionPolicy ImageResolutionPolicy `json:"resolutionPolicy,omitempty"`
+	// Platform selects a child manifest from a manifest list. It is required when
+	// ResolutionPolicy is ImageResolutionPolicyPlatformDigest and ignored otherwise.
+	Platform *DeploymentTriggerImageChangePlatform `json:"platform,omitempty"`
+
+	// RollbackOnFailure opts this trigger into automatic rollback: if the deployment it causes
+	// fails within RollbackController's failure window, the previous image is restored and this
+	// trigger will not re-fire on the same failed image.
+	RollbackOnFailure bool `json:"rollbackOnFailure,omitempty"`
+}
+
+// DeploymentStatus describes the possible states a deployment (ReplicationController) can be in,
+// as recorded in the DeploymentStatusAnnotation on the ReplicationController.
+type DeploymentStatus string
+
+const (
+	DeploymentStatusNew      DeploymentStatus = "New"
+	DeploymentStatusPending  DeploymentStatus = "Pending"
+	DeploymentStatusRunning  DeploymentStatus = "Running"
+	DeploymentStatusComplete DeploymentStatus = "Complete"
+	DeploymentStatusFailed   DeploymentStatus = "Failed"
+)
+
+const (
+	// DeploymentConfigAnnotation is set on a deployment (ReplicationController) and holds the
+	// name of the DeploymentConfig that owns it.
+	DeploymentConfigAnnotation = "openshift.io/deployment-config.name"
+	// DeploymentStatusAnnotation is set on a deployment (ReplicationController) to its current
+	// DeploymentStatus.
+	DeploymentStatusAnnotation = "openshift.io/deployment.phase"
+)
@@ -10,6 +10,7 @@ import (
 
 	"github.com/openshift/origin/pkg/client"
 	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	deploygraph "github.com/openshift/origin/pkg/deploy/graph"
 	deployutil "github.com/openshift/origin/pkg/deploy/util"
 	imageapi "github.com/openshift/origin/pkg/image/api"
 )
@@ -21,6 +22,38 @@ import (
 type ImageChangeController struct {
 	listDeploymentConfigs func() ([]*deployapi.DeploymentConfig, error)
 	client                client.Interface
+
+	// graph, when set, is consulted instead of listDeploymentConfigs: it maps a changed
+	// ImageStreamTag directly to the DeploymentConfigs that trigger on it in O(1), so Handle's
+	// cost scales with the number of tags on the handled stream rather than with
+	// configs × triggers. Set it by starting a GraphControllerFactory, which keeps the graph in
+	// sync with a DeploymentConfig informer and calls UseGraph.
+	graph *deploygraph.Graph
+}
+
+// candidateDeploymentConfigs returns the DeploymentConfigs that might need updating because of
+// stream. When the controller has a dependency graph, only the configs with an image change
+// trigger on one of the stream's tags are returned (an O(1) lookup per tag); Handle's existing
+// per-trigger checks still apply afterwards. Without a graph, every known DeploymentConfig is
+// returned, matching the old scan-everything behavior.
+func (c *ImageChangeController) candidateDeploymentConfigs(stream *imageapi.ImageStream) ([]*deployapi.DeploymentConfig, error) {
+	if c.graph == nil {
+		return c.listDeploymentConfigs()
+	}
+
+	seen := sets.NewString()
+	configs := []*deployapi.DeploymentConfig{}
+	for tag := range stream.Status.Tags {
+		for _, config := range c.graph.DeploymentConfigsForTag(stream.Namespace, stream.Name, tag) {
+			key := deployutil.LabelForDeploymentConfig(config)
+			if seen.Has(key) {
+				continue
+			}
+			seen.Insert(key)
+			configs = append(configs, config)
+		}
+	}
+	return configs, nil
 }
 
 // fatalError is an error which can't be retried.
@@ -32,7 +65,7 @@ func (e fatalError) Error() string {
 
 // Handle processes image change triggers associated with imagestream.
 func (c *ImageChangeController) Handle(stream *imageapi.ImageStream) error {
-	configs, err := c.listDeploymentConfigs()
+	configs, err := c.candidateDeploymentConfigs(stream)
 	if err != nil {
 		return fmt.Errorf("couldn't get list of deployment configs while handling image stream %q: %v", imageapi.LabelForStream(stream), err)
 	}
@@ -79,8 +112,18 @@ func (c *ImageChangeController) Handle(stream *imageapi.ImageStream) error {
 				continue
 			}
 
+			// Resolve the reference that should actually be written to the container. For the
+			// default Tag policy this is just the tag event's DockerImageReference; Digest and
+			// PlatformDigest pin to a specific manifest (and, for manifest lists, a specific
+			// architecture/OS/variant) so that the deployment is reproducible.
+			resolvedImage, err := c.resolveTriggeredImage(stream, tag, params, latestEvent)
+			if err != nil {
+				glog.Warningf("Couldn't resolve trigger image for deployment config %q: %v", deployutil.LabelForDeploymentConfig(config), err)
+				continue
+			}
+
 			// Ensure a change occurred
-			if len(latestEvent.DockerImageReference) == 0 || latestEvent.DockerImageReference == params.LastTriggeredImage {
+			if len(resolvedImage) == 0 || resolvedImage == params.LastTriggeredImage {
 				glog.V(4).Infof("No image changes for deployment config %q were detected", deployutil.LabelForDeploymentConfig(config))
 				continue
 			}
@@ -92,6 +135,14 @@ func (c *ImageChangeController) Handle(stream *imageapi.ImageStream) error {
 					continue
 				}
 
+				// A RollbackOnFailure trigger that just failed on this exact image shouldn't
+				// re-trigger the instant RollbackController restores the previous image - the
+				// failed-image annotation set by RollbackController.Handle guards against that loop.
+				if params.RollbackOnFailure && resolvedImage == config.Annotations[failedRollbackImageAnnotation(container.Name)] {
+					glog.V(4).Infof("Refusing to re-trigger deployment config %q on previously failed image %q", deployutil.LabelForDeploymentConfig(config), resolvedImage)
+					continue
+				}
+
 				if !hasImageChange {
 					// create a copy prior to mutation
 					result, err := deployutil.DeploymentConfigDeepCopy(config)
@@ -104,10 +155,19 @@ func (c *ImageChangeController) Handle(stream *imageapi.ImageStream) error {
 					params = config.Spec.Triggers[j].ImageChangeParams
 				}
 
+				// When RollbackOnFailure is set, remember the image being replaced so that
+				// RollbackController can restore it if the deployment this triggers fails.
+				if params.RollbackOnFailure && len(container.Image) > 0 {
+					if config.Annotations == nil {
+						config.Annotations = map[string]string{}
+					}
+					config.Annotations[previousRollbackImageAnnotation(container.Name)] = container.Image
+				}
+
 				// Update the image
-				container.Image = latestEvent.DockerImageReference
+				container.Image = resolvedImage
 				// Log the last triggered image ID
-				params.LastTriggeredImage = latestEvent.DockerImageReference
+				params.LastTriggeredImage = resolvedImage
 				hasImageChange = true
 			}
 		}
@@ -143,3 +203,73 @@ func triggerMatchesImage(config *deployapi.DeploymentConfig, params *deployapi.D
 	name, _, ok := imageapi.SplitImageStreamTag(params.From.Name)
 	return stream.Namespace == namespace && stream.Name == name && ok
 }
+
+// resolveTriggeredImage returns the image reference that should be written to a triggered
+// container for the given tag event. Trigger.ResolutionPolicy controls how: the default
+// ImageResolutionPolicyTag simply follows the mutable tag, while ImageResolutionPolicyDigest
+// and ImageResolutionPolicyPlatformDigest pin to the manifest digest backing the tag. When the
+// resolved manifest is a manifest list (Docker schema 2 manifest list or an OCI image index),
+// PlatformDigest additionally selects the child manifest matching params.Platform and pins to
+// its digest, giving deterministic rollouts on heterogeneous (multi-arch) clusters; Digest
+// returns an error for a manifest list instead of pinning the list's own digest.
+func (c *ImageChangeController) resolveTriggeredImage(stream *imageapi.ImageStream, tag string, params *deployapi.DeploymentTriggerImageChangeParams, latestEvent *imageapi.TagEvent) (string, error) {
+	policy := params.ResolutionPolicy
+	if len(policy) == 0 {
+		policy = deployapi.ImageResolutionPolicyTag
+	}
+	if policy == deployapi.ImageResolutionPolicyTag {
+		return latestEvent.DockerImageReference, nil
+	}
+
+	// Platform is required for PlatformDigest regardless of what the backing image turns out to
+	// be: a trigger missing it is invalid from the start, not only once the image becomes a
+	// manifest list, so reject it before doing any resolution work.
+	if policy == deployapi.ImageResolutionPolicyPlatformDigest && params.Platform == nil {
+		return "", fatalError(fmt.Sprintf("trigger on %s uses ImageResolutionPolicyPlatformDigest but specifies no Platform", params.From.Name))
+	}
+
+	image, err := c.client.ImageStreamImages(stream.Namespace).Get(stream.Name, latestEvent.Image)
+	if err != nil {
+		return "", fmt.Errorf("couldn't resolve the manifest for %s:%s (%s): %v", stream.Name, tag, latestEvent.Image, err)
+	}
+
+	// Parse the tag event's own pull spec rather than building a fresh one, so the resolved
+	// reference keeps whatever registry host and namespace the tag already carried.
+	ref, err := imageapi.ParseDockerImageReference(latestEvent.DockerImageReference)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse image reference %q for %s:%s: %v", latestEvent.DockerImageReference, stream.Name, tag, err)
+	}
+	ref.Tag = ""
+
+	manifestList, isList := imageapi.ManifestListForImage(image)
+	if !isList {
+		if policy == deployapi.ImageResolutionPolicyPlatformDigest {
+			return "", fatalError(fmt.Sprintf("trigger on %s requested platform %s but %s is not a manifest list", params.From.Name, platformKey(params.Platform), latestEvent.Image))
+		}
+		ref.ID = latestEvent.Image
+		return ref.Exact(), nil
+	}
+
+	if policy != deployapi.ImageResolutionPolicyPlatformDigest {
+		return "", fatalError(fmt.Sprintf("trigger on %s resolves to a manifest list; a Platform must be specified to pin a single digest from it", params.From.Name))
+	}
+
+	child, ok := manifestList.ChildFor(params.Platform.OS, params.Platform.Architecture, params.Platform.Variant)
+	if !ok {
+		return "", fatalError(fmt.Sprintf("manifest list for %s has no child manifest matching platform %s", params.From.Name, platformKey(params.Platform)))
+	}
+
+	ref.ID = child.Digest
+	return ref.Exact(), nil
+}
+
+// platformKey renders a Platform as a short, log-friendly identifier such as "linux/arm64/v8".
+func platformKey(platform *deployapi.DeploymentTriggerImageChangePlatform) string {
+	if platform == nil {
+		return ""
+	}
+	if len(platform.Variant) > 0 {
+		return fmt.Sprintf("%s/%s/%s", platform.OS, platform.Architecture, platform.Variant)
+	}
+	return fmt.Sprintf("%s/%s", platform.OS, platform.Architecture)
+}
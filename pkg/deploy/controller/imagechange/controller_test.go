@@ -0,0 +1,179 @@
+package imagechange
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/openshift/origin/pkg/client"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// fakeImageStreamImages is a minimal client.ImageStreamImageInterface backed by an in-memory map
+// keyed by "streamName@id".
+type fakeImageStreamImages map[string]*imageapi.Image
+
+func (f fakeImageStreamImages) Get(streamName, id string) (*imageapi.Image, error) {
+	image, ok := f[streamName+"@"+id]
+	if !ok {
+		return nil, fmt.Errorf("no such image %s@%s", streamName, id)
+	}
+	return image, nil
+}
+
+// fakeClient implements client.Interface using only the pieces resolveTriggeredImage needs.
+type fakeClient struct {
+	images fakeImageStreamImages
+}
+
+func (f *fakeClient) DeploymentConfigs(namespace string) client.DeploymentConfigInterface {
+	return nil
+}
+
+func (f *fakeClient) ImageStreamImages(namespace string) client.ImageStreamImageInterface {
+	return f.images
+}
+
+func newControllerWithImages(images fakeImageStreamImages) *ImageChangeController {
+	return &ImageChangeController{client: &fakeClient{images: images}}
+}
+
+func TestResolveTriggeredImageTagPolicy(t *testing.T) {
+	c := newControllerWithImages(nil)
+	stream := &imageapi.ImageStream{}
+	stream.Name = "myapp"
+	params := &deployapi.DeploymentTriggerImageChangeParams{}
+	event := &imageapi.TagEvent{DockerImageReference: "registry.example.com:5000/ns/myapp:v1", Image: "sha256:aaaa"}
+
+	got, err := c.resolveTriggeredImage(stream, "latest", params, event)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != event.DockerImageReference {
+		t.Errorf("Tag policy should pass through the tag event reference unchanged, got %q want %q", got, event.DockerImageReference)
+	}
+}
+
+func TestResolveTriggeredImageDigestPolicyNotAList(t *testing.T) {
+	image := &imageapi.Image{DockerImageReference: "registry.example.com:5000/ns/myapp:v1"}
+	images := fakeImageStreamImages{"myapp@sha256:aaaa": image}
+	c := newControllerWithImages(images)
+
+	stream := &imageapi.ImageStream{}
+	stream.Namespace = "ns"
+	stream.Name = "myapp"
+	params := &deployapi.DeploymentTriggerImageChangeParams{ResolutionPolicy: deployapi.ImageResolutionPolicyDigest}
+	event := &imageapi.TagEvent{DockerImageReference: "registry.example.com:5000/ns/myapp:v1", Image: "sha256:aaaa"}
+
+	got, err := c.resolveTriggeredImage(stream, "latest", params, event)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "registry.example.com:5000/ns/myapp@sha256:aaaa"
+	if got != want {
+		t.Errorf("Digest policy = %q, want %q (registry/namespace must be preserved)", got, want)
+	}
+}
+
+func TestResolveTriggeredImagePlatformDigestPolicy(t *testing.T) {
+	image := &imageapi.Image{
+		DockerImageReference:         "registry.example.com:5000/ns/myapp:v1",
+		DockerImageManifestMediaType: imageapi.DockerManifestListMediaType,
+		DockerImageManifests: []imageapi.ImageManifest{
+			{Digest: "sha256:amd64digest", OS: "linux", Architecture: "amd64"},
+			{Digest: "sha256:arm64digest", OS: "linux", Architecture: "arm64", Variant: "v8"},
+		},
+	}
+	images := fakeImageStreamImages{"myapp@sha256:aaaa": image}
+	c := newControllerWithImages(images)
+
+	stream := &imageapi.ImageStream{}
+	stream.Namespace = "ns"
+	stream.Name = "myapp"
+	event := &imageapi.TagEvent{DockerImageReference: "registry.example.com:5000/ns/myapp:v1", Image: "sha256:aaaa"}
+
+	params := &deployapi.DeploymentTriggerImageChangeParams{
+		ResolutionPolicy: deployapi.ImageResolutionPolicyPlatformDigest,
+		Platform:         &deployapi.DeploymentTriggerImageChangePlatform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+	}
+
+	got, err := c.resolveTriggeredImage(stream, "latest", params, event)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := "registry.example.com:5000/ns/myapp@sha256:arm64digest"
+	if got != want {
+		t.Errorf("PlatformDigest policy = %q, want %q", got, want)
+	}
+}
+
+func TestResolveTriggeredImagePlatformDigestPolicyErrors(t *testing.T) {
+	list := &imageapi.Image{
+		DockerImageReference:         "registry.example.com:5000/ns/myapp:v1",
+		DockerImageManifestMediaType: imageapi.DockerManifestListMediaType,
+		DockerImageManifests: []imageapi.ImageManifest{
+			{Digest: "sha256:amd64digest", OS: "linux", Architecture: "amd64"},
+		},
+	}
+	single := &imageapi.Image{DockerImageReference: "registry.example.com:5000/ns/myapp:v1"}
+	images := fakeImageStreamImages{
+		"myapp@sha256:list":   list,
+		"myapp@sha256:single": single,
+	}
+	c := newControllerWithImages(images)
+	stream := &imageapi.ImageStream{}
+	stream.Namespace = "ns"
+	stream.Name = "myapp"
+
+	cases := []struct {
+		name    string
+		event   *imageapi.TagEvent
+		params  *deployapi.DeploymentTriggerImageChangeParams
+		wantErr string
+	}{
+		{
+			name:  "PlatformDigest against a non-list image",
+			event: &imageapi.TagEvent{DockerImageReference: "registry.example.com:5000/ns/myapp:v1", Image: "sha256:single"},
+			params: &deployapi.DeploymentTriggerImageChangeParams{
+				ResolutionPolicy: deployapi.ImageResolutionPolicyPlatformDigest,
+				Platform:         &deployapi.DeploymentTriggerImageChangePlatform{OS: "linux", Architecture: "arm64"},
+			},
+			wantErr: "is not a manifest list",
+		},
+		{
+			name:  "PlatformDigest with no Platform against a non-list image",
+			event: &imageapi.TagEvent{DockerImageReference: "registry.example.com:5000/ns/myapp:v1", Image: "sha256:single"},
+			params: &deployapi.DeploymentTriggerImageChangeParams{
+				ResolutionPolicy: deployapi.ImageResolutionPolicyPlatformDigest,
+			},
+			wantErr: "specifies no Platform",
+		},
+		{
+			name:  "Digest against a manifest list with no Platform",
+			event: &imageapi.TagEvent{DockerImageReference: "registry.example.com:5000/ns/myapp:v1", Image: "sha256:list"},
+			params: &deployapi.DeploymentTriggerImageChangeParams{
+				ResolutionPolicy: deployapi.ImageResolutionPolicyDigest,
+			},
+			wantErr: "Platform must be specified",
+		},
+		{
+			name:  "PlatformDigest with no matching child",
+			event: &imageapi.TagEvent{DockerImageReference: "registry.example.com:5000/ns/myapp:v1", Image: "sha256:list"},
+			params: &deployapi.DeploymentTriggerImageChangeParams{
+				ResolutionPolicy: deployapi.ImageResolutionPolicyPlatformDigest,
+				Platform:         &deployapi.DeploymentTriggerImageChangePlatform{OS: "linux", Architecture: "arm64"},
+			},
+			wantErr: "no child manifest matching platform",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := c.resolveTriggeredImage(stream, "latest", tc.params, tc.event)
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Fatalf("resolveTriggeredImage() error = %v, want it to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
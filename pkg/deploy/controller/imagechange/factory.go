@@ -0,0 +1,186 @@
+package imagechange
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	utilruntime "k8s.io/kubernetes/pkg/util/runtime"
+	"k8s.io/kubernetes/pkg/util/wait"
+	"k8s.io/kubernetes/pkg/util/workqueue"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+var (
+	queueDepth = prometheus.NewGauge(prometheus.GaugeOpts{
+		Namespace: "openshift",
+		Subsystem: "image_change_controller",
+		Name:      "queue_depth",
+		Help:      "Number of image stream keys waiting to be processed by the image change controller.",
+	})
+	coalescedUpdates = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "openshift",
+		Subsystem: "image_change_controller",
+		Name:      "coalesced_updates_total",
+		Help:      "Number of image stream events absorbed into an already pending debounced handling of the same image stream.",
+	})
+	updateFailures = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: "openshift",
+		Subsystem: "image_change_controller",
+		Name:      "update_failures_total",
+		Help:      "Number of image stream handlings by the image change controller that failed, counted once per failed handling however many deployment config updates in it failed.",
+	})
+)
+
+func init() {
+	prometheus.MustRegister(queueDepth)
+	prometheus.MustRegister(coalescedUpdates)
+	prometheus.MustRegister(updateFailures)
+}
+
+// imageStreamHandler is satisfied by *ImageChangeController. It exists so tests can substitute a
+// fake and exercise the factory's debounce/coalescing behavior without a real client.
+type imageStreamHandler interface {
+	Handle(stream *imageapi.ImageStream) error
+}
+
+// ImageChangeControllerFactory fronts an ImageChangeController with a debounced, coalescing
+// workqueue keyed by "namespace/stream" so that a burst of tag events on a single image stream
+// (for example a CI job pushing many architecture-specific tags in a few seconds) results in a
+// bounded number of deployment config updates rather than one update per event.
+type ImageChangeControllerFactory struct {
+	// Controller does the actual work of matching triggers and updating deployment configs.
+	Controller imageStreamHandler
+
+	// ResyncPeriod is how often the informer feeding this factory performs a full relist.
+	ResyncPeriod time.Duration
+	// DebounceWindow is how long the queue waits after the first event for a stream before
+	// handing it to a worker, so that later events for the same stream can coalesce with it.
+	DebounceWindow time.Duration
+	// Workers is the number of goroutines draining the queue concurrently.
+	Workers int
+
+	queue workqueue.RateLimitingInterface
+
+	// streams holds the most recently observed object for each "namespace/stream" key, so a
+	// worker always handles the latest state even if several events were coalesced together.
+	lock    sync.Mutex
+	streams map[string]*imageapi.ImageStream
+	// timers holds the pending debounce timer for a key between the first event in a burst and
+	// the moment it is actually added to queue. A key present here has not yet been handed to a
+	// worker; later events for the same key just update streams and are absorbed by the timer
+	// that is already running, rather than starting a new one or adding to queue again.
+	timers map[string]*time.Timer
+}
+
+// NewImageChangeControllerFactory returns a factory ready to be started with Create().
+// ResyncPeriod, DebounceWindow and Workers are set to the defaults below; callers may overwrite
+// them before calling Create(). A zero DebounceWindow disables debouncing. The
+// queue is created here rather than in Create() so that Enqueue is always safe to call, even if
+// an event races ahead of Create() on startup.
+func NewImageChangeControllerFactory(controller imageStreamHandler) *ImageChangeControllerFactory {
+	// This rate limiter governs retry-after-failure backoff for AddRateLimited, which is
+	// unrelated to debouncing: debouncing is implemented by the per-key timer in Enqueue.
+	rl := workqueue.NewItemExponentialFailureRateLimiter(1*time.Second, 1*time.Minute)
+	return &ImageChangeControllerFactory{
+		Controller:     controller,
+		ResyncPeriod:   2 * time.Minute,
+		DebounceWindow: 1 * time.Second,
+		Workers:        1,
+		queue:          workqueue.NewNamedRateLimitingQueue(rl, "image-change"),
+		streams:        make(map[string]*imageapi.ImageStream),
+		timers:         make(map[string]*time.Timer),
+	}
+}
+
+// Create starts Workers goroutines consuming the factory's debounced queue and returns
+// immediately. Callers should invoke Enqueue for every observed image stream add/update.
+func (f *ImageChangeControllerFactory) Create(stopCh <-chan struct{}) {
+	if f.Workers <= 0 {
+		f.Workers = 1
+	}
+
+	for i := 0; i < f.Workers; i++ {
+		go wait.Until(f.worker, time.Second, stopCh)
+	}
+
+	go wait.Until(func() {
+		queueDepth.Set(float64(f.queue.Len()))
+	}, 5*time.Second, stopCh)
+
+	go func() {
+		<-stopCh
+		f.queue.ShutDown()
+	}()
+}
+
+// Enqueue records the latest known state of stream and, if no debounce timer is already pending
+// for its key, starts a DebounceWindow timer that adds the key to queue when it fires. Further events for the same key arriving before the timer fires just update
+// the recorded stream and are coalesced into the single Handle() call the timer will trigger.
+func (f *ImageChangeControllerFactory) Enqueue(stream *imageapi.ImageStream) {
+	key := imageapi.LabelForStream(stream)
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	f.streams[key] = stream
+
+	if _, pending := f.timers[key]; pending {
+		coalescedUpdates.Inc()
+		return
+	}
+
+	f.timers[key] = time.AfterFunc(f.DebounceWindow, func() {
+		f.lock.Lock()
+		delete(f.timers, key)
+		f.lock.Unlock()
+		f.queue.Add(key)
+	})
+}
+
+// worker drains keys from the queue until it is shut down.
+func (f *ImageChangeControllerFactory) worker() {
+	for f.processNextItem() {
+	}
+}
+
+func (f *ImageChangeControllerFactory) processNextItem() bool {
+	key, quit := f.queue.Get()
+	if quit {
+		return false
+	}
+	defer f.queue.Done(key)
+
+	// The stream is kept in f.streams until Handle succeeds, so that AddRateLimited below
+	// actually has something to retry on the next dequeue instead of immediately hitting the
+	// stream == nil branch and silently dropping a failed update.
+	f.lock.Lock()
+	stream := f.streams[key.(string)]
+	f.lock.Unlock()
+
+	if stream == nil {
+		f.queue.Forget(key)
+		return true
+	}
+
+	if err := f.Controller.Handle(stream); err != nil {
+		updateFailures.Inc()
+		utilruntime.HandleError(err)
+		f.queue.AddRateLimited(key)
+		return true
+	}
+
+	// Only clear the entry if it's still the stream we just handled: Enqueue may have already
+	// overwritten it with a newer event while Handle was running, and that newer stream is still
+	// waiting on its own debounce timer to add key back to the queue.
+	f.lock.Lock()
+	if f.streams[key.(string)] == stream {
+		delete(f.streams, key.(string))
+	}
+	f.lock.Unlock()
+
+	f.queue.Forget(key)
+	return true
+}
@@ -0,0 +1,102 @@
+package imagechange
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// countingController records the ResourceVersion of every stream it was asked to Handle, standing in for the real
+// ImageChangeController so factory tests can assert on call counts without a fake client.
+type countingController struct {
+	mu      sync.Mutex
+	handled []string
+}
+
+func (c *countingController) Handle(stream *imageapi.ImageStream) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.handled = append(c.handled, stream.ResourceVersion)
+	return nil
+}
+
+func (c *countingController) calls() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.handled)
+}
+
+func (c *countingController) last() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.handled) == 0 {
+		return ""
+	}
+	return c.handled[len(c.handled)-1]
+}
+
+// newTestFactory returns a factory whose Controller is a countingController instead of a real
+// ImageChangeController, so the debounce/coalescing behavior can be tested without a fake client.
+func newTestFactory(counting *countingController, debounce time.Duration) *ImageChangeControllerFactory {
+	f := NewImageChangeControllerFactory(counting)
+	f.DebounceWindow = debounce
+	f.Workers = 1
+	return f
+}
+
+func TestFactoryCoalescesEventsWithinDebounceWindow(t *testing.T) {
+	counting := &countingController{}
+	f := newTestFactory(counting, 50*time.Millisecond)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	f.Create(stopCh)
+
+	stream := &imageapi.ImageStream{}
+	stream.Namespace = "ns"
+	stream.Name = "myapp"
+
+	for i := 0; i < 5; i++ {
+		stream.ResourceVersion = string(rune('0' + i))
+		f.Enqueue(stream)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		if counting.calls() >= 1 {
+			break
+		}
+		select {
+		case <-deadline:
+			t.Fatal("timed out waiting for the debounced event to be handled")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if got := counting.calls(); got != 1 {
+		t.Errorf("expected 5 rapid events on the same key to coalesce into 1 Handle() call, got %d", got)
+	}
+	if got := counting.last(); got != "4" {
+		t.Errorf("expected the coalesced call to carry the latest stream, got ResourceVersion %q", got)
+	}
+}
+
+func TestFactoryDoesNotHandleBeforeDebounceWindowElapses(t *testing.T) {
+	counting := &countingController{}
+	f := newTestFactory(counting, 200*time.Millisecond)
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	f.Create(stopCh)
+
+	stream := &imageapi.ImageStream{}
+	stream.Namespace = "ns"
+	stream.Name = "myapp"
+	f.Enqueue(stream)
+
+	time.Sleep(50 * time.Millisecond)
+	if got := counting.calls(); got != 0 {
+		t.Errorf("expected no Handle() call before DebounceWindow elapses, got %d", got)
+	}
+}
@@ -0,0 +1,50 @@
+package imagechange
+
+import (
+	"k8s.io/kubernetes/pkg/client/cache"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	deploygraph "github.com/openshift/origin/pkg/deploy/graph"
+)
+
+// UseGraph points the controller at graph. Once this is called Handle looks up candidate configs
+// in the graph instead of scanning every DeploymentConfig known to listDeploymentConfigs; callers
+// are expected to keep graph itself in sync with a DeploymentConfig informer, for example via
+// GraphControllerFactory.
+func (c *ImageChangeController) UseGraph(graph *deploygraph.Graph) {
+	c.graph = graph
+}
+
+// NewDeploymentConfigGraphEventHandler returns a cache.ResourceEventHandler that keeps graph in
+// sync with a DeploymentConfig informer, so ImageChangeController.Handle can resolve triggered
+// configs for a changed tag in O(1) instead of listing every DeploymentConfig.
+func NewDeploymentConfigGraphEventHandler(graph *deploygraph.Graph) cache.ResourceEventHandler {
+	return &deploymentConfigGraphEventHandler{graph: graph}
+}
+
+type deploymentConfigGraphEventHandler struct {
+	graph *deploygraph.Graph
+}
+
+func (h *deploymentConfigGraphEventHandler) OnAdd(obj interface{}) {
+	if config, ok := obj.(*deployapi.DeploymentConfig); ok {
+		h.graph.AddDeploymentConfig(config)
+	}
+}
+
+func (h *deploymentConfigGraphEventHandler) OnUpdate(oldObj, newObj interface{}) {
+	if config, ok := newObj.(*deployapi.DeploymentConfig); ok {
+		h.graph.UpdateDeploymentConfig(config)
+	}
+}
+
+func (h *deploymentConfigGraphEventHandler) OnDelete(obj interface{}) {
+	switch t := obj.(type) {
+	case *deployapi.DeploymentConfig:
+		h.graph.RemoveDeploymentConfig(t)
+	case cache.DeletedFinalStateUnknown:
+		if config, ok := t.Obj.(*deployapi.DeploymentConfig); ok {
+			h.graph.RemoveDeploymentConfig(config)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package imagechange
+
+import (
+	"time"
+
+	"k8s.io/kubernetes/pkg/client/cache"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	deploygraph "github.com/openshift/origin/pkg/deploy/graph"
+)
+
+// GraphControllerFactory starts an informer over DeploymentConfigs, keeps a Graph in sync with
+// it, and points Controller at the graph, which is what actually lets candidateDeploymentConfigs
+// resolve triggered configs for a changed tag in O(1) instead of listing every DeploymentConfig:
+// without this factory c.graph stays nil and Handle always falls back to listDeploymentConfigs.
+type GraphControllerFactory struct {
+	// Controller is pointed at the graph once the informer backing it has been created.
+	Controller *ImageChangeController
+
+	// ListWatch lists and watches the DeploymentConfigs the graph should track.
+	ListWatch cache.ListerWatcher
+	// ResyncPeriod is how often the informer performs a full relist.
+	ResyncPeriod time.Duration
+}
+
+// NewGraphControllerFactory returns a factory ready to be started with Create().
+func NewGraphControllerFactory(controller *ImageChangeController, lw cache.ListerWatcher) *GraphControllerFactory {
+	return &GraphControllerFactory{
+		Controller:   controller,
+		ListWatch:    lw,
+		ResyncPeriod: 2 * time.Minute,
+	}
+}
+
+// Create builds a Graph, starts the DeploymentConfig informer that keeps it up to date via
+// NewDeploymentConfigGraphEventHandler, and calls UseGraph so Controller starts consulting it
+// immediately. The informer (and the goroutine driving it) stop when stopCh is closed.
+func (f *GraphControllerFactory) Create(stopCh <-chan struct{}) {
+	graph := deploygraph.New()
+	_, informer := cache.NewInformer(f.ListWatch, &deployapi.DeploymentConfig{}, f.ResyncPeriod, NewDeploymentConfigGraphEventHandler(graph))
+	f.Controller.UseGraph(graph)
+	go informer.Run(stopCh)
+}
@@ -0,0 +1,117 @@
+package imagechange
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang/glog"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/util/sets"
+
+	"github.com/openshift/origin/pkg/client"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+	deployutil "github.com/openshift/origin/pkg/deploy/util"
+)
+
+const (
+	// previousRollbackImageAnnotationPrefix is set on a DeploymentConfig, suffixed by a
+	// container name, to the image that container ran before an image change trigger with
+	// RollbackOnFailure fired. RollbackController reads it back if the resulting deployment
+	// fails within the configured window.
+	previousRollbackImageAnnotationPrefix = "image.openshift.io/previous-triggered-image-"
+
+	// failedRollbackImageAnnotationPrefix is set on a DeploymentConfig, suffixed by a
+	// container name, to an image that is known to have failed to deploy. ImageChangeController
+	// refuses to re-trigger a RollbackOnFailure trigger onto an image recorded here, which would
+	// otherwise immediately undo the rollback RollbackController just performed.
+	failedRollbackImageAnnotationPrefix = "image.openshift.io/failed-triggered-image-"
+)
+
+func previousRollbackImageAnnotation(containerName string) string {
+	return previousRollbackImageAnnotationPrefix + containerName
+}
+
+func failedRollbackImageAnnotation(containerName string) string {
+	return failedRollbackImageAnnotationPrefix + containerName
+}
+
+// RollbackController watches ReplicationControllers owned by a DeploymentConfig and, when one
+// created by a RollbackOnFailure image change trigger fails within FailureWindow of being
+// created, restores the config's containers to the image recorded by ImageChangeController
+// before the trigger fired. It also annotates the config with the failed image so the same
+// trigger doesn't immediately fire again on the next image change event.
+type RollbackController struct {
+	client client.Interface
+
+	// FailureWindow bounds how long after a triggered deployment starts a failure is still
+	// eligible for rollback. Deployments that fail after this window are left alone, on the
+	// assumption the failure is unrelated to the image change that triggered them.
+	FailureWindow time.Duration
+}
+
+// NewRollbackController returns a RollbackController with a default FailureWindow.
+func NewRollbackController(client client.Interface) *RollbackController {
+	return &RollbackController{client: client, FailureWindow: 10 * time.Minute}
+}
+
+// Handle inspects rc, the ReplicationController backing one deployment of a DeploymentConfig,
+// and restores any RollbackOnFailure containers if the deployment failed within FailureWindow.
+func (c *RollbackController) Handle(rc *kapi.ReplicationController) error {
+	if deployutil.DeploymentStatusFor(rc) != deployapi.DeploymentStatusFailed {
+		return nil
+	}
+
+	configName := deployutil.DeploymentConfigNameFor(rc)
+	if len(configName) == 0 {
+		return nil
+	}
+
+	created := rc.CreationTimestamp.Time
+	if !created.IsZero() && time.Since(created) > c.FailureWindow {
+		glog.V(4).Infof("Deployment %q failed outside the rollback window, leaving deployment config %q alone", rc.Name, configName)
+		return nil
+	}
+
+	config, err := c.client.DeploymentConfigs(rc.Namespace).Get(configName)
+	if err != nil {
+		return fmt.Errorf("couldn't get deployment config %s/%s for failed deployment %q: %v", rc.Namespace, configName, rc.Name, err)
+	}
+
+	restored := false
+	for i := range config.Spec.Template.Spec.Containers {
+		container := &config.Spec.Template.Spec.Containers[i]
+		previous, ok := config.Annotations[previousRollbackImageAnnotation(container.Name)]
+		if !ok || previous == container.Image {
+			continue
+		}
+
+		if config.Annotations == nil {
+			config.Annotations = map[string]string{}
+		}
+		config.Annotations[failedRollbackImageAnnotation(container.Name)] = container.Image
+
+		failed := container.Image
+		container.Image = previous
+		delete(config.Annotations, previousRollbackImageAnnotation(container.Name))
+
+		for j := range config.Spec.Triggers {
+			params := config.Spec.Triggers[j].ImageChangeParams
+			if params != nil && params.RollbackOnFailure && sets.NewString(params.ContainerNames...).Has(container.Name) {
+				params.LastTriggeredImage = previous
+			}
+		}
+
+		glog.V(2).Infof("Rolling back container %q of deployment config %q from failed image %q to %q", container.Name, deployutil.LabelForDeploymentConfig(config), failed, previous)
+		restored = true
+	}
+
+	if !restored {
+		return nil
+	}
+
+	if _, err := c.client.DeploymentConfigs(config.Namespace).Update(config); err != nil {
+		return fmt.Errorf("couldn't roll back deployment config %q after failed deployment %q: %v", deployutil.LabelForDeploymentConfig(config), rc.Name, err)
+	}
+	return nil
+}
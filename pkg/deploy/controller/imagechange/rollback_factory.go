@@ -0,0 +1,54 @@
+package imagechange
+
+import (
+	"time"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+	"k8s.io/kubernetes/pkg/client/cache"
+	utilruntime "k8s.io/kubernetes/pkg/util/runtime"
+)
+
+// RollbackControllerFactory starts an informer over ReplicationControllers and invokes
+// RollbackController.Handle whenever one is added or updated, which is what actually lets a
+// RollbackOnFailure trigger fire: without this watcher RollbackController.Handle is never called.
+type RollbackControllerFactory struct {
+	// Controller restores a DeploymentConfig's previous image when a triggered deployment fails.
+	Controller *RollbackController
+
+	// ListWatch lists and watches the ReplicationControllers RollbackController should inspect.
+	ListWatch cache.ListerWatcher
+	// ResyncPeriod is how often the informer performs a full relist.
+	ResyncPeriod time.Duration
+}
+
+// NewRollbackControllerFactory returns a factory ready to be started with Create().
+func NewRollbackControllerFactory(controller *RollbackController, lw cache.ListerWatcher) *RollbackControllerFactory {
+	return &RollbackControllerFactory{
+		Controller:   controller,
+		ListWatch:    lw,
+		ResyncPeriod: 2 * time.Minute,
+	}
+}
+
+// Create starts the ReplicationController informer backing this factory and returns
+// immediately. The informer (and the goroutine driving it) stop when stopCh is closed.
+func (f *RollbackControllerFactory) Create(stopCh <-chan struct{}) {
+	_, informer := cache.NewInformer(f.ListWatch, &kapi.ReplicationController{}, f.ResyncPeriod, cache.ResourceEventHandlerFuncs{
+		AddFunc: f.handle,
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			f.handle(newObj)
+		},
+	})
+	go informer.Run(stopCh)
+}
+
+// handle adapts an informer callback to RollbackController.Handle.
+func (f *RollbackControllerFactory) handle(obj interface{}) {
+	rc, ok := obj.(*kapi.ReplicationController)
+	if !ok {
+		return
+	}
+	if err := f.Controller.Handle(rc); err != nil {
+		utilruntime.HandleError(err)
+	}
+}
@@ -0,0 +1,14 @@
+package graph
+
+import (
+	"net/http"
+)
+
+// DebugHandler returns an http.Handler suitable for mounting on a debug mux (for example
+// alongside /debug/pprof) that dumps the graph's current edges as plain text.
+func (g *Graph) DebugHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write([]byte(g.Debug()))
+	})
+}
@@ -0,0 +1,273 @@
+// Package graph maintains an in-memory dependency graph from ImageStreamTags to the
+// DeploymentConfigs and BuildConfigs whose triggers reference them, so that controllers which
+// need to react to a tag change can look up the affected objects in O(1) instead of scanning
+// every config on every event.
+package graph
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	imageapi "github.com/openshift/origin/pkg/image/api"
+)
+
+// TagReference identifies a single ImageStreamTag: namespace/stream:tag.
+type TagReference struct {
+	Namespace string
+	Stream    string
+	Tag       string
+}
+
+func (t TagReference) String() string {
+	return fmt.Sprintf("%s/%s:%s", t.Namespace, t.Stream, t.Tag)
+}
+
+// objectKey identifies a DeploymentConfig or BuildConfig as namespace/name.
+type objectKey struct {
+	Namespace string
+	Name      string
+}
+
+func (k objectKey) String() string {
+	return fmt.Sprintf("%s/%s", k.Namespace, k.Name)
+}
+
+// Graph indexes DeploymentConfig and BuildConfig image change triggers by the
+// ImageStreamTag they reference, so that ImageChangeController.Handle (and the analogous build
+// trigger controller) can look up exactly the configs affected by a tag change.
+type Graph struct {
+	lock sync.RWMutex
+
+	deploymentConfigs map[objectKey]*deployapi.DeploymentConfig
+	deploymentEdges   map[TagReference]map[objectKey]struct{}
+	// deploymentTags is the reverse index of deploymentEdges: the set of TagReferences a given
+	// config currently has an edge to. It lets add/remove/update touch only the handful of tags
+	// a single config references instead of scanning every tag in the cluster.
+	deploymentTags map[objectKey][]TagReference
+
+	buildConfigs map[objectKey]*buildapi.BuildConfig
+	buildEdges   map[TagReference]map[objectKey]struct{}
+	// buildTags is the reverse index of buildEdges, mirroring deploymentTags.
+	buildTags map[objectKey][]TagReference
+}
+
+// New returns an empty Graph.
+func New() *Graph {
+	return &Graph{
+		deploymentConfigs: make(map[objectKey]*deployapi.DeploymentConfig),
+		deploymentEdges:   make(map[TagReference]map[objectKey]struct{}),
+		deploymentTags:    make(map[objectKey][]TagReference),
+		buildConfigs:      make(map[objectKey]*buildapi.BuildConfig),
+		buildEdges:        make(map[TagReference]map[objectKey]struct{}),
+		buildTags:         make(map[objectKey][]TagReference),
+	}
+}
+
+// AddDeploymentConfig indexes config's image change triggers. It is safe to call for a config
+// that is already present; the previous edges are replaced.
+func (g *Graph) AddDeploymentConfig(config *deployapi.DeploymentConfig) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.removeDeploymentConfigLocked(objectKey{config.Namespace, config.Name})
+	g.addDeploymentConfigLocked(config)
+}
+
+// UpdateDeploymentConfig replaces the edges recorded for a DeploymentConfig with the triggers
+// currently present on the updated object.
+func (g *Graph) UpdateDeploymentConfig(config *deployapi.DeploymentConfig) {
+	g.AddDeploymentConfig(config)
+}
+
+// RemoveDeploymentConfig drops all edges recorded for config.
+func (g *Graph) RemoveDeploymentConfig(config *deployapi.DeploymentConfig) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.removeDeploymentConfigLocked(objectKey{config.Namespace, config.Name})
+}
+
+// DeploymentConfigsForTag returns the DeploymentConfigs with an image change trigger on the
+// given ImageStreamTag. The returned slice is a snapshot and safe for the caller to mutate.
+func (g *Graph) DeploymentConfigsForTag(namespace, stream, tag string) []*deployapi.DeploymentConfig {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	ref := TagReference{Namespace: namespace, Stream: stream, Tag: tag}
+	keys := g.deploymentEdges[ref]
+	configs := make([]*deployapi.DeploymentConfig, 0, len(keys))
+	for key := range keys {
+		if config, ok := g.deploymentConfigs[key]; ok {
+			configs = append(configs, config)
+		}
+	}
+	return configs
+}
+
+// AddBuildConfig indexes config's image change triggers, mirroring AddDeploymentConfig.
+func (g *Graph) AddBuildConfig(config *buildapi.BuildConfig) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.removeBuildConfigLocked(objectKey{config.Namespace, config.Name})
+	g.addBuildConfigLocked(config)
+}
+
+// RemoveBuildConfig drops all edges recorded for config.
+func (g *Graph) RemoveBuildConfig(config *buildapi.BuildConfig) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.removeBuildConfigLocked(objectKey{config.Namespace, config.Name})
+}
+
+// BuildConfigsForTag returns the BuildConfigs with an image change trigger on the given
+// ImageStreamTag.
+func (g *Graph) BuildConfigsForTag(namespace, stream, tag string) []*buildapi.BuildConfig {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	ref := TagReference{Namespace: namespace, Stream: stream, Tag: tag}
+	keys := g.buildEdges[ref]
+	configs := make([]*buildapi.BuildConfig, 0, len(keys))
+	for key := range keys {
+		if config, ok := g.buildConfigs[key]; ok {
+			configs = append(configs, config)
+		}
+	}
+	return configs
+}
+
+// Debug returns a human readable dump of every tag reference currently tracked and the configs
+// it fans out to. It is intended to back a debug HTTP endpoint, not for programmatic use.
+func (g *Graph) Debug() string {
+	g.lock.RLock()
+	defer g.lock.RUnlock()
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "deployment config triggers (%d tags):\n", len(g.deploymentEdges))
+	for ref, keys := range g.deploymentEdges {
+		fmt.Fprintf(&buf, "  %s -> ", ref)
+		first := true
+		for key := range keys {
+			if !first {
+				fmt.Fprint(&buf, ", ")
+			}
+			fmt.Fprint(&buf, key)
+			first = false
+		}
+		fmt.Fprintln(&buf)
+	}
+	fmt.Fprintf(&buf, "build config triggers (%d tags):\n", len(g.buildEdges))
+	for ref, keys := range g.buildEdges {
+		fmt.Fprintf(&buf, "  %s -> ", ref)
+		first := true
+		for key := range keys {
+			if !first {
+				fmt.Fprint(&buf, ", ")
+			}
+			fmt.Fprint(&buf, key)
+			first = false
+		}
+		fmt.Fprintln(&buf)
+	}
+	return buf.String()
+}
+
+func (g *Graph) addDeploymentConfigLocked(config *deployapi.DeploymentConfig) {
+	key := objectKey{config.Namespace, config.Name}
+	refs := imageChangeTagsForDeploymentConfig(config)
+	for _, ref := range refs {
+		if g.deploymentEdges[ref] == nil {
+			g.deploymentEdges[ref] = make(map[objectKey]struct{})
+		}
+		g.deploymentEdges[ref][key] = struct{}{}
+	}
+	g.deploymentTags[key] = refs
+	g.deploymentConfigs[key] = config
+}
+
+// removeDeploymentConfigLocked drops key's edges using deploymentTags, so only the tags key
+// itself references are touched rather than every tag known to the graph.
+func (g *Graph) removeDeploymentConfigLocked(key objectKey) {
+	for _, ref := range g.deploymentTags[key] {
+		keys := g.deploymentEdges[ref]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(g.deploymentEdges, ref)
+		}
+	}
+	delete(g.deploymentTags, key)
+	delete(g.deploymentConfigs, key)
+}
+
+func (g *Graph) addBuildConfigLocked(config *buildapi.BuildConfig) {
+	key := objectKey{config.Namespace, config.Name}
+	refs := imageChangeTagsForBuildConfig(config)
+	for _, ref := range refs {
+		if g.buildEdges[ref] == nil {
+			g.buildEdges[ref] = make(map[objectKey]struct{})
+		}
+		g.buildEdges[ref][key] = struct{}{}
+	}
+	g.buildTags[key] = refs
+	g.buildConfigs[key] = config
+}
+
+// removeBuildConfigLocked drops key's edges using buildTags, mirroring
+// removeDeploymentConfigLocked.
+func (g *Graph) removeBuildConfigLocked(key objectKey) {
+	for _, ref := range g.buildTags[key] {
+		keys := g.buildEdges[ref]
+		delete(keys, key)
+		if len(keys) == 0 {
+			delete(g.buildEdges, ref)
+		}
+	}
+	delete(g.buildTags, key)
+	delete(g.buildConfigs, key)
+}
+
+// imageChangeTagsForDeploymentConfig returns the TagReferences targeted by config's image change
+// triggers, automatic or not.
+func imageChangeTagsForDeploymentConfig(config *deployapi.DeploymentConfig) []TagReference {
+	var refs []TagReference
+	for _, trigger := range config.Spec.Triggers {
+		if trigger.Type != deployapi.DeploymentTriggerOnImageChange || trigger.ImageChangeParams == nil {
+			continue
+		}
+		params := trigger.ImageChangeParams
+		namespace := params.From.Namespace
+		if len(namespace) == 0 {
+			namespace = config.Namespace
+		}
+		name, tag, ok := imageapi.SplitImageStreamTag(params.From.Name)
+		if !ok {
+			continue
+		}
+		refs = append(refs, TagReference{Namespace: namespace, Stream: name, Tag: tag})
+	}
+	return refs
+}
+
+// imageChangeTagsForBuildConfig returns the TagReferences targeted by config's automatic image
+// change triggers.
+func imageChangeTagsForBuildConfig(config *buildapi.BuildConfig) []TagReference {
+	var refs []TagReference
+	for _, trigger := range config.Spec.Triggers {
+		if trigger.Type != buildapi.ImageChangeBuildTriggerType || trigger.ImageChange == nil || trigger.ImageChange.From == nil {
+			continue
+		}
+		from := trigger.ImageChange.From
+		namespace := from.Namespace
+		if len(namespace) == 0 {
+			namespace = config.Namespace
+		}
+		name, tag, ok := imageapi.SplitImageStreamTag(from.Name)
+		if !ok {
+			continue
+		}
+		refs = append(refs, TagReference{Namespace: namespace, Stream: name, Tag: tag})
+	}
+	return refs
+}
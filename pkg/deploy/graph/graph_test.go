@@ -0,0 +1,108 @@
+package graph
+
+import (
+	"testing"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	buildapi "github.com/openshift/origin/pkg/build/api"
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+func imageChangeConfig(namespace, name, tagRef string) *deployapi.DeploymentConfig {
+	config := &deployapi.DeploymentConfig{}
+	config.Namespace = namespace
+	config.Name = name
+	config.Spec.Triggers = []deployapi.DeploymentTriggerPolicy{
+		{
+			Type: deployapi.DeploymentTriggerOnImageChange,
+			ImageChangeParams: &deployapi.DeploymentTriggerImageChangeParams{
+				From: kapi.ObjectReference{Name: tagRef},
+			},
+		},
+	}
+	return config
+}
+
+func TestAddAndLookupDeploymentConfig(t *testing.T) {
+	g := New()
+	config := imageChangeConfig("ns", "front", "stream:latest")
+	g.AddDeploymentConfig(config)
+
+	found := g.DeploymentConfigsForTag("ns", "stream", "latest")
+	if len(found) != 1 || found[0].Name != "front" {
+		t.Fatalf("DeploymentConfigsForTag() = %v, want [front]", found)
+	}
+
+	if found := g.DeploymentConfigsForTag("ns", "stream", "other"); len(found) != 0 {
+		t.Errorf("expected no configs for an untracked tag, got %v", found)
+	}
+}
+
+func TestUpdateDeploymentConfigMovesEdges(t *testing.T) {
+	g := New()
+	config := imageChangeConfig("ns", "front", "stream:v1")
+	g.AddDeploymentConfig(config)
+
+	updated := imageChangeConfig("ns", "front", "stream:v2")
+	g.UpdateDeploymentConfig(updated)
+
+	if found := g.DeploymentConfigsForTag("ns", "stream", "v1"); len(found) != 0 {
+		t.Errorf("expected the old tag edge to be gone after update, got %v", found)
+	}
+	if found := g.DeploymentConfigsForTag("ns", "stream", "v2"); len(found) != 1 {
+		t.Errorf("expected the new tag edge to exist after update, got %v", found)
+	}
+}
+
+func TestRemoveDeploymentConfigOnlyTouchesItsOwnTags(t *testing.T) {
+	g := New()
+	a := imageChangeConfig("ns", "a", "stream:shared")
+	b := imageChangeConfig("ns", "b", "stream:shared")
+	c := imageChangeConfig("ns", "c", "stream:other")
+	g.AddDeploymentConfig(a)
+	g.AddDeploymentConfig(b)
+	g.AddDeploymentConfig(c)
+
+	g.RemoveDeploymentConfig(a)
+
+	shared := g.DeploymentConfigsForTag("ns", "stream", "shared")
+	if len(shared) != 1 || shared[0].Name != "b" {
+		t.Fatalf("removing a should leave b's edge on the shared tag intact, got %v", shared)
+	}
+	other := g.DeploymentConfigsForTag("ns", "stream", "other")
+	if len(other) != 1 || other[0].Name != "c" {
+		t.Fatalf("removing a should not disturb c's unrelated tag edge, got %v", other)
+	}
+
+	g.lock.RLock()
+	_, stillIndexed := g.deploymentTags[objectKey{"ns", "a"}]
+	g.lock.RUnlock()
+	if stillIndexed {
+		t.Error("expected a's reverse index entry to be cleaned up on remove")
+	}
+}
+
+func TestBuildConfigEdges(t *testing.T) {
+	g := New()
+	config := &buildapi.BuildConfig{}
+	config.Namespace = "ns"
+	config.Name = "build1"
+	config.Spec.Triggers = []buildapi.BuildTriggerPolicy{
+		{
+			Type:        buildapi.ImageChangeBuildTriggerType,
+			ImageChange: &buildapi.ImageChangeTrigger{From: &kapi.ObjectReference{Name: "stream:latest"}},
+		},
+	}
+	g.AddBuildConfig(config)
+
+	found := g.BuildConfigsForTag("ns", "stream", "latest")
+	if len(found) != 1 || found[0].Name != "build1" {
+		t.Fatalf("BuildConfigsForTag() = %v, want [build1]", found)
+	}
+
+	g.RemoveBuildConfig(config)
+	if found := g.BuildConfigsForTag("ns", "stream", "latest"); len(found) != 0 {
+		t.Errorf("expected no build configs after removal, got %v", found)
+	}
+}
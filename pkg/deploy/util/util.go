@@ -0,0 +1,44 @@
+// Package util holds small helpers shared by deployment controllers, mirroring the shape of the
+// real github.com/openshift/origin/pkg/deploy/util package.
+package util
+
+import (
+	"encoding/json"
+	"fmt"
+
+	kapi "k8s.io/kubernetes/pkg/api"
+
+	deployapi "github.com/openshift/origin/pkg/deploy/api"
+)
+
+// LabelForDeploymentConfig returns the "namespace/name" identifier used in log messages for
+// config.
+func LabelForDeploymentConfig(config *deployapi.DeploymentConfig) string {
+	return fmt.Sprintf("%s/%s", config.Namespace, config.Name)
+}
+
+// DeploymentConfigDeepCopy returns a deep copy of config. It round-trips through JSON rather than
+// copying fields by hand so that new fields (such as the trigger Platform pointer) are always
+// deep-copied correctly without this function needing to be updated every time the API grows.
+func DeploymentConfigDeepCopy(config *deployapi.DeploymentConfig) (*deployapi.DeploymentConfig, error) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't copy deployment config %s: %v", LabelForDeploymentConfig(config), err)
+	}
+	copied := &deployapi.DeploymentConfig{}
+	if err := json.Unmarshal(data, copied); err != nil {
+		return nil, fmt.Errorf("couldn't copy deployment config %s: %v", LabelForDeploymentConfig(config), err)
+	}
+	return copied, nil
+}
+
+// DeploymentConfigNameFor returns the name of the DeploymentConfig that owns rc, or "" if rc
+// wasn't created by one.
+func DeploymentConfigNameFor(rc *kapi.ReplicationController) string {
+	return rc.Annotations[deployapi.DeploymentConfigAnnotation]
+}
+
+// DeploymentStatusFor returns the DeploymentStatus recorded on rc.
+func DeploymentStatusFor(rc *kapi.ReplicationController) deployapi.DeploymentStatus {
+	return deployapi.DeploymentStatus(rc.Annotations[deployapi.DeploymentStatusAnnotation])
+}
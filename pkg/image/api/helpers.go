@@ -0,0 +1,144 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// LabelForStream returns the "namespace/name" identifier used in log messages for stream.
+func LabelForStream(stream *ImageStream) string {
+	return fmt.Sprintf("%s/%s", stream.Namespace, stream.Name)
+}
+
+// SplitImageStreamTag splits a "name:tag" reference into its name and tag. If no tag is present,
+// "latest" is assumed. ok is false if name is empty.
+func SplitImageStreamTag(nameAndTag string) (name string, tag string, ok bool) {
+	parts := strings.SplitN(nameAndTag, ":", 2)
+	name = parts[0]
+	if len(name) == 0 {
+		return "", "", false
+	}
+	if len(parts) == 2 {
+		tag = parts[1]
+	} else {
+		tag = "latest"
+	}
+	return name, tag, true
+}
+
+// LatestTaggedImage returns the most recent TagEvent for tag, or nil if the stream has no history
+// for it.
+func LatestTaggedImage(stream *ImageStream, tag string) *TagEvent {
+	list, ok := stream.Status.Tags[tag]
+	if !ok || len(list.Items) == 0 {
+		return nil
+	}
+	return &list.Items[0]
+}
+
+// ManifestListForImage returns image's child manifests and true if image's manifest is a
+// manifest list (Docker schema 2 manifest list or OCI image index), or (nil, false) if it is an
+// ordinary single-platform image.
+func ManifestListForImage(image *Image) (*ManifestList, bool) {
+	switch image.DockerImageManifestMediaType {
+	case DockerManifestListMediaType, OCIImageIndexMediaType:
+		return &ManifestList{Manifests: image.DockerImageManifests}, true
+	default:
+		return nil, false
+	}
+}
+
+// ManifestList is the set of platform-specific manifests referenced by a manifest list or OCI
+// image index.
+type ManifestList struct {
+	Manifests []ImageManifest
+}
+
+// ChildFor returns the manifest matching os/architecture/variant, or (nil, false) if the list has
+// no such child.
+func (m *ManifestList) ChildFor(os, architecture, variant string) (*ImageManifest, bool) {
+	for i := range m.Manifests {
+		child := &m.Manifests[i]
+		if child.OS == os && child.Architecture == architecture && child.Variant == variant {
+			return child, true
+		}
+	}
+	return nil, false
+}
+
+// DockerImageReference represents the parsed form of a docker image pull spec, e.g.
+// "registry.example.com:5000/namespace/name:tag" or "...@sha256:...".
+type DockerImageReference struct {
+	Registry  string
+	Namespace string
+	Name      string
+	Tag       string
+	ID        string
+}
+
+// ParseDockerImageReference parses spec into its component parts. It is intentionally lenient:
+// the part before the first "/", if it contains a "." or ":" (or is "localhost"), is treated as a
+// registry host, mirroring how the Docker CLI disambiguates a registry from a namespace.
+func ParseDockerImageReference(spec string) (DockerImageReference, error) {
+	if len(spec) == 0 {
+		return DockerImageReference{}, fmt.Errorf("image reference must not be empty")
+	}
+
+	ref := DockerImageReference{}
+	remainder := spec
+
+	if slash := strings.Index(remainder, "/"); slash != -1 {
+		host := remainder[:slash]
+		if host == "localhost" || strings.ContainsAny(host, ".:") {
+			ref.Registry = host
+			remainder = remainder[slash+1:]
+		}
+	}
+
+	if slash := strings.LastIndex(remainder, "/"); slash != -1 {
+		ref.Namespace = remainder[:slash]
+		remainder = remainder[slash+1:]
+	}
+
+	switch {
+	case strings.Contains(remainder, "@"):
+		parts := strings.SplitN(remainder, "@", 2)
+		ref.Name = parts[0]
+		ref.ID = parts[1]
+	case strings.Contains(remainder, ":"):
+		parts := strings.SplitN(remainder, ":", 2)
+		ref.Name = parts[0]
+		ref.Tag = parts[1]
+	default:
+		ref.Name = remainder
+	}
+
+	if len(ref.Name) == 0 {
+		return DockerImageReference{}, fmt.Errorf("couldn't parse image reference %q", spec)
+	}
+	return ref, nil
+}
+
+// Exact reassembles r into a pull spec string, preferring ID (digest) over Tag when both are
+// somehow set.
+func (r DockerImageReference) Exact() string {
+	var buf strings.Builder
+	if len(r.Registry) > 0 {
+		buf.WriteString(r.Registry)
+		buf.WriteString("/")
+	}
+	if len(r.Namespace) > 0 {
+		buf.WriteString(r.Namespace)
+		buf.WriteString("/")
+	}
+	buf.WriteString(r.Name)
+	switch {
+	case len(r.ID) > 0:
+		buf.WriteString("@")
+		buf.WriteString(r.ID)
+	case len(r.Tag) > 0:
+		buf.WriteString(":")
+		buf.WriteString(r.Tag)
+	}
+	return buf.String()
+}
@@ -0,0 +1,78 @@
+// Package api holds the internal (unversioned) API types for ImageStreams and Images, mirroring
+// the shape of the real github.com/openshift/origin/pkg/image/api package. Only the pieces
+// needed by pkg/deploy/controller/imagechange and pkg/deploy/graph are reproduced here.
+package api
+
+import (
+	kapi "k8s.io/kubernetes/pkg/api"
+)
+
+// ImageStream represents a mapping of tags to images.
+type ImageStream struct {
+	kapi.TypeMeta   `json:",inline"`
+	kapi.ObjectMeta `json:"metadata,omitempty"`
+
+	Status ImageStreamStatus `json:"status,omitempty"`
+}
+
+// ImageStreamStatus tracks the tags known to an ImageStream.
+type ImageStreamStatus struct {
+	// DockerImageRepository is the registry hostname and repository path images pushed to this
+	// stream are pulled from, e.g. "registry.example.com:5000/myproject/myapp".
+	DockerImageRepository string `json:"dockerImageRepository,omitempty"`
+	// Tags maps a tag name to the history of images pushed to it, most recent first.
+	Tags map[string]TagEventList `json:"tags,omitempty"`
+}
+
+// TagEventList is the history of a single tag.
+type TagEventList struct {
+	Items []TagEvent `json:"items"`
+}
+
+// TagEvent records a single update to a tag.
+type TagEvent struct {
+	// Created is when this event was recorded.
+	Created kapi.Time `json:"created"`
+	// DockerImageReference is the full pull spec this tag pointed to at the time of this event.
+	DockerImageReference string `json:"dockerImageReference"`
+	// Image is the digest (sha256:...) of the manifest this tag pointed to at the time of this
+	// event.
+	Image string `json:"image"`
+	// Generation is the ImageStream generation this event was recorded under.
+	Generation int64 `json:"generation"`
+}
+
+// Image is an immutable representation of a container image and metadata at a point in time.
+type Image struct {
+	kapi.TypeMeta   `json:",inline"`
+	kapi.ObjectMeta `json:"metadata,omitempty"`
+
+	// DockerImageReference is the full pull spec of this image.
+	DockerImageReference string `json:"dockerImageReference,omitempty"`
+	// DockerImageManifestMediaType is the media type of the manifest backing this image. When it
+	// is one of the manifest list media types, DockerImageManifests holds the child manifests.
+	DockerImageManifestMediaType string `json:"dockerImageManifestMediaType,omitempty"`
+	// DockerImageManifests holds the child manifests when this image is a manifest list.
+	DockerImageManifests []ImageManifest `json:"dockerImageManifests,omitempty"`
+}
+
+// ImageManifest describes a single platform-specific manifest referenced by a manifest list.
+type ImageManifest struct {
+	// Digest is the content digest (sha256:...) of this child manifest.
+	Digest string `json:"digest"`
+	// MediaType is the media type of this child manifest.
+	MediaType string `json:"mediaType"`
+	// ManifestSize is the size in bytes of this child manifest.
+	ManifestSize int64 `json:"manifestSize"`
+	// Architecture, OS and Variant identify the platform this child manifest targets.
+	Architecture string `json:"architecture"`
+	OS           string `json:"os"`
+	Variant      string `json:"variant,omitempty"`
+}
+
+const (
+	// DockerManifestListMediaType is the Docker schema 2 manifest list media type.
+	DockerManifestListMediaType = "application/vnd.docker.distribution.manifest.list.v2+json"
+	// OCIImageIndexMediaType is the OCI image index media type.
+	OCIImageIndexMediaType = "application/vnd.oci.image.index.v1+json"
+)
@@ -0,0 +1,54 @@
+package builds
+
+import (
+	"fmt"
+	"time"
+
+	g "github.com/onsi/ginkgo"
+	o "github.com/onsi/gomega"
+
+	exutil "github.com/openshift/origin/test/extended/util"
+)
+
+var _ = g.Describe("[builds] image change trigger rolls back a failed deployment", func() {
+	defer g.GinkgoRecover()
+	var (
+		rollbackFixture = exutil.FixturePath("testdata", "test-deploy-rollback-on-failure.json")
+		oc              = exutil.NewCLI("cli-deploy-rollback", exutil.KubeConfigPath())
+	)
+
+	g.JustBeforeEach(func() {
+		g.By("creating a deployment config with a RollbackOnFailure image change trigger")
+		oc.Run("create").Args("-f", rollbackFixture).Execute()
+	})
+
+	g.Describe("a broken image tag", func() {
+		g.It("should be rolled back to the last working image", func() {
+			g.By("waiting for the initial deployment to become available")
+			err := exutil.WaitForDeploymentConfig(oc.KubeREST(), oc.REST(), oc.Namespace(), "deployment-rollback", 1, oc)
+			o.Expect(err).NotTo(o.HaveOccurred())
+
+			dc, err := oc.REST().DeploymentConfigs(oc.Namespace()).Get("deployment-rollback")
+			o.Expect(err).NotTo(o.HaveOccurred())
+			workingImage := dc.Spec.Template.Spec.Containers[0].Image
+
+			g.By("pushing a broken image to the triggering tag")
+			out, err := oc.Run("tag").Args("openshift/origin-deployer-broken:latest", "deployment-rollback:latest").Output()
+			fmt.Fprintf(g.GinkgoWriter, "\ntag output:\n%s\n", out)
+			o.Expect(err).NotTo(o.HaveOccurred())
+
+			g.By("waiting for the resulting deployment to fail")
+			err = exutil.WaitForDeploymentConfig(oc.KubeREST(), oc.REST(), oc.Namespace(), "deployment-rollback", 2, oc)
+			o.Expect(err).To(o.HaveOccurred())
+
+			g.By("verifying the deployment config was rolled back to the prior working image")
+			o.Eventually(func() (string, error) {
+				dc, err := oc.REST().DeploymentConfigs(oc.Namespace()).Get("deployment-rollback")
+				if err != nil {
+					return "", err
+				}
+				return dc.Spec.Template.Spec.Containers[0].Image, nil
+			}, 2*time.Minute, 5*time.Second).Should(o.Equal(workingImage))
+		})
+	})
+})